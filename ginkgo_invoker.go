@@ -0,0 +1,191 @@
+// ginkgo_invoker.go
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ginkgoVersion identifies which major version of Ginkgo a repo's go.mod
+// requires, since the `ginkgo` CLI refuses to run against a module built
+// with a mismatched version.
+type ginkgoVersion int
+
+const (
+	ginkgoVersionUnknown ginkgoVersion = iota
+	ginkgoVersionV1
+	ginkgoVersionV2
+)
+
+// versionMismatchMarker is the string Ginkgo's CLI prints when the
+// installed `ginkgo` binary's major version doesn't match the one
+// required by the module under test.
+const versionMismatchMarker = "version mismatch"
+
+// ginkgoInvocation is the cached decision for how to run Ginkgo in a
+// given repo: which version it needs, and whether the `ginkgo` binary
+// works or `go run` has to be used instead.
+type ginkgoInvocation struct {
+	version  ginkgoVersion
+	useGoRun bool
+}
+
+// ginkgoInvoker runs Ginkgo test suites, detecting the module's required
+// Ginkgo version and falling back from the installed `ginkgo` binary to
+// `go run` on a version mismatch. Decisions are cached per repo so later
+// attempts against the same repo skip the probe.
+type ginkgoInvoker struct {
+	mu    sync.Mutex
+	cache map[string]ginkgoInvocation
+}
+
+func newGinkgoInvoker() *ginkgoInvoker {
+	return &ginkgoInvoker{cache: make(map[string]ginkgoInvocation)}
+}
+
+var defaultGinkgoInvoker = newGinkgoInvoker()
+
+// run executes Ginkgo against dir with args, reusing the cached
+// invocation decision for repoPath if one exists, and probing (then
+// caching) one otherwise.
+func (g *ginkgoInvoker) run(repoPath, dir string, args []string) (string, error) {
+	g.mu.Lock()
+	decision, cached := g.cache[repoPath]
+	g.mu.Unlock()
+
+	if !cached {
+		decision = ginkgoInvocation{version: detectGinkgoVersion(dir)}
+	}
+
+	runArgs := args
+	switch decision.version {
+	case ginkgoVersionV2:
+		runArgs = translateFlagsForV2(args)
+	case ginkgoVersionV1:
+		runArgs = translateFlagsForV1(args)
+	}
+
+	if !decision.useGoRun {
+		output, err := runGinkgoBinary(dir, runArgs)
+		if err == nil || !strings.Contains(output, versionMismatchMarker) {
+			g.cacheDecision(repoPath, decision)
+			return output, err
+		}
+		decision.useGoRun = true
+	}
+
+	output, err := runGinkgoViaGoRun(dir, decision.version, runArgs)
+	g.cacheDecision(repoPath, decision)
+	return output, err
+}
+
+// versionFor returns the cached Ginkgo version decision for repoPath, or
+// ginkgoVersionUnknown if run hasn't been called for it yet.
+func (g *ginkgoInvoker) versionFor(repoPath string) ginkgoVersion {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cache[repoPath].version
+}
+
+func (g *ginkgoInvoker) cacheDecision(repoPath string, decision ginkgoInvocation) {
+	g.mu.Lock()
+	g.cache[repoPath] = decision
+	g.mu.Unlock()
+}
+
+// detectGinkgoVersion walks up from dir to the nearest go.mod and reports
+// whether it requires github.com/onsi/ginkgo (v1) or
+// github.com/onsi/ginkgo/v2; go.mod files further up are not consulted.
+func detectGinkgoVersion(dir string) ginkgoVersion {
+	for d := dir; ; {
+		f, err := os.Open(filepath.Join(d, "go.mod"))
+		if err == nil {
+			version := scanGoModForGinkgo(f)
+			f.Close()
+			return version
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return ginkgoVersionUnknown
+		}
+		d = parent
+	}
+}
+
+func scanGoModForGinkgo(f *os.File) ginkgoVersion {
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.Contains(line, "github.com/onsi/ginkgo/v2"):
+			return ginkgoVersionV2
+		case strings.Contains(line, "github.com/onsi/ginkgo "):
+			return ginkgoVersionV1
+		}
+	}
+	return ginkgoVersionUnknown
+}
+
+// translateFlagsForV2 rewrites v1-only flag spellings (e.g. -nodes) to
+// their v2 equivalents (--procs) so callers can pass one flag set
+// regardless of which binary ends up running.
+func translateFlagsForV2(args []string) []string {
+	translated := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "-nodes="):
+			translated = append(translated, "--procs="+strings.TrimPrefix(arg, "-nodes="))
+		case arg == "-nodes":
+			translated = append(translated, "--procs")
+		default:
+			translated = append(translated, arg)
+		}
+	}
+	return translated
+}
+
+// translateFlagsForV1 rewrites v2-only flag spellings to their v1
+// equivalents, and drops flags v1 has no equivalent for at all
+// (--json-report, --junit-report), so a repo pinned to Ginkgo v1 doesn't
+// fail on an unrecognized flag. Callers that drop these flags need a
+// stdout-scraping fallback instead of loadGinkgoJSONReport; see
+// parseGinkgoV1Output.
+func translateFlagsForV1(args []string) []string {
+	translated := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--json-report="), strings.HasPrefix(arg, "--junit-report="):
+			continue
+		case strings.HasPrefix(arg, "--flake-attempts="):
+			translated = append(translated, "-flakeAttempts="+strings.TrimPrefix(arg, "--flake-attempts="))
+		case arg == "--no-color":
+			translated = append(translated, "-noColor")
+		default:
+			translated = append(translated, arg)
+		}
+	}
+	return translated
+}
+
+func runGinkgoBinary(dir string, args []string) (string, error) {
+	cmd := exec.Command("ginkgo", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func runGinkgoViaGoRun(dir string, version ginkgoVersion, args []string) (string, error) {
+	pkg := "github.com/onsi/ginkgo/ginkgo"
+	if version == ginkgoVersionV2 {
+		pkg = "github.com/onsi/ginkgo/v2/ginkgo"
+	}
+	goArgs := append([]string{"run", pkg}, args...)
+	cmd := exec.Command("go", goArgs...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,214 @@
+// ginkgo_report.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// specState mirrors the leaf-node state strings Ginkgo writes into its
+// --json-report output (types.SpecState in github.com/onsi/ginkgo/v2).
+type specState string
+
+const (
+	specPassed   specState = "passed"
+	specFailed   specState = "failed"
+	specPanicked specState = "panicked"
+	specPending  specState = "pending"
+	specSkipped  specState = "skipped"
+	specFlaky    specState = "flaky"
+)
+
+// ginkgoLocation is a file:line pointer into a spec's source, as Ginkgo
+// reports it.
+type ginkgoLocation struct {
+	FileName   string `json:"FileName"`
+	LineNumber int    `json:"LineNumber"`
+}
+
+// ginkgoFailure carries the failure or panic message and stack trace for
+// a spec that didn't pass outright.
+type ginkgoFailure struct {
+	Message    string         `json:"Message"`
+	Location   ginkgoLocation `json:"Location"`
+	StackTrace string         `json:"StackTrace"`
+}
+
+// ginkgoSpecReport is the subset of a single spec's JSON report this
+// runner cares about.
+type ginkgoSpecReport struct {
+	LeafNodeText     string         `json:"LeafNodeText"`
+	LeafNodeLocation ginkgoLocation `json:"LeafNodeLocation"`
+	State            specState      `json:"State"`
+	NumAttempts      int            `json:"NumAttempts"`
+	Failure          ginkgoFailure  `json:"Failure"`
+}
+
+// ginkgoSuiteReport is one element of the top-level array Ginkgo writes
+// with --json-report (one per suite run in the invocation).
+type ginkgoSuiteReport struct {
+	SuiteDescription string             `json:"SuiteDescription"`
+	SpecReports      []ginkgoSpecReport `json:"SpecReports"`
+}
+
+// specResult is a flattened, report-friendly view of one failing or
+// flaky spec.
+type specResult struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message,omitempty"`
+}
+
+// testReport is the structured, machine-readable result of a single
+// repo's test run. It replaces scraping failure/flake markers out of
+// raw Ginkgo stdout.
+type testReport struct {
+	Repo    string       `json:"repo"`
+	Failed  []specResult `json:"failed"`
+	Flaky   []specResult `json:"flaky"`
+	Passed  int          `json:"passed"`
+	Pending int          `json:"pending"`
+	Skipped int          `json:"skipped"`
+}
+
+// loadGinkgoJSONReport reads the --json-report file Ginkgo wrote into
+// reportDir and folds every suite's specs into a single testReport for
+// repoName.
+func loadGinkgoJSONReport(reportDir, repoName string) (*testReport, error) {
+	path := filepath.Join(reportDir, "ginkgo.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading ginkgo json report: %w", err)
+	}
+	var suites []ginkgoSuiteReport
+	if err := json.Unmarshal(data, &suites); err != nil {
+		return nil, fmt.Errorf("parsing ginkgo json report: %w", err)
+	}
+
+	report := &testReport{Repo: repoName}
+	for _, suite := range suites {
+		for _, spec := range suite.SpecReports {
+			switch spec.State {
+			case specFailed, specPanicked:
+				report.Failed = append(report.Failed, specResult{
+					Name:    spec.LeafNodeText,
+					File:    spec.LeafNodeLocation.FileName,
+					Line:    spec.LeafNodeLocation.LineNumber,
+					Message: spec.Failure.Message,
+				})
+			case specFlaky:
+				report.Flaky = append(report.Flaky, specResult{
+					Name:    spec.LeafNodeText,
+					File:    spec.LeafNodeLocation.FileName,
+					Line:    spec.LeafNodeLocation.LineNumber,
+					Message: spec.Failure.Message,
+				})
+			case specPassed:
+				report.Passed++
+			case specPending:
+				report.Pending++
+			case specSkipped:
+				report.Skipped++
+			}
+		}
+	}
+	return report, nil
+}
+
+// renderSummary produces the human-readable text block generateSummary
+// used to hand-build from regex matches, now derived from the structured
+// report, with each spec's file:line appended to its name.
+func (r *testReport) renderSummary(criticalError string) string {
+	var b strings.Builder
+	if criticalError != "" {
+		b.WriteString(fmt.Sprintf("Critical Error:\n  - %s\n", criticalError))
+		return b.String()
+	}
+	if len(r.Failed) > 0 {
+		b.WriteString("Failing Tests:\n")
+		for _, f := range r.Failed {
+			b.WriteString(fmt.Sprintf("  - %s (%s:%d)\n", f.Name, f.File, f.Line))
+		}
+	}
+	if len(r.Flaky) > 0 {
+		b.WriteString("\nFlaky Tests:\n")
+		for _, f := range r.Flaky {
+			b.WriteString(fmt.Sprintf("  - %s (%s:%d)\n", f.Name, f.File, f.Line))
+		}
+	}
+	if b.Len() == 0 {
+		return "No failing or flaky tests detected.\n"
+	}
+	return b.String()
+}
+
+// writeJSON persists the structured report as <repo>.summary.json in dir
+// so downstream CI can consume results without re-parsing stdout.
+func (r *testReport) writeJSON(dir string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling test report for %s: %w", r.Repo, err)
+	}
+	return os.WriteFile(filepath.Join(dir, r.Repo+".summary.json"), data, 0644)
+}
+
+// copyJUnitReport copies the junit.xml Ginkgo wrote into testDir out to
+// <repo>.junit.xml in dir, if one was produced. A missing file (e.g. the
+// suite failed to compile before Ginkgo could write it) is not an error.
+func copyJUnitReport(testDir, dir, repoName string) error {
+	data, err := os.ReadFile(filepath.Join(testDir, "junit.xml"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading junit report for %s: %w", repoName, err)
+	}
+	return os.WriteFile(filepath.Join(dir, repoName+".junit.xml"), data, 0644)
+}
+
+// v1FailMarker and v1FlakyMarker match the summary lines Ginkgo v1
+// prints to stdout for failed and flaky specs, used as a fallback when
+// loadGinkgoJSONReport can't run because v1 has no --json-report flag.
+var (
+	v1FailMarker  = regexp.MustCompile(`\[FAIL\]`)
+	v1FlakyMarker = regexp.MustCompile(`\[FLAKY\]`)
+)
+
+// parseGinkgoV1Output scrapes failed and flaky spec lines out of raw
+// Ginkgo v1 stdout, since v1 has no structured report to read instead.
+// Passed, Pending and Skipped are left at zero. Unlike
+// loadGinkgoJSONReport, the resulting specResults have no file:line
+// location, only a name taken from the matching output line.
+func parseGinkgoV1Output(output, repoName string) *testReport {
+	report := &testReport{Repo: repoName}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case v1FailMarker.MatchString(line):
+			report.Failed = append(report.Failed, specResult{Name: line})
+		case v1FlakyMarker.MatchString(line):
+			report.Flaky = append(report.Flaky, specResult{Name: line})
+		}
+	}
+	return report
+}
+
+// mergeSpecResults appends any result from fresh not already present
+// (by file:line+name) in seen, recording it in seen as it goes.
+func mergeSpecResults(dst []specResult, fresh []specResult, seen map[string]bool) []specResult {
+	for _, r := range fresh {
+		key := fmt.Sprintf("%s:%d:%s", r.File, r.Line, r.Name)
+		if !seen[key] {
+			dst = append(dst, r)
+			seen[key] = true
+		}
+	}
+	return dst
+}
@@ -0,0 +1,113 @@
+// ginkgo_report_test.go
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeSpecResults(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  []specResult
+		in   []specResult
+		want []specResult
+	}{
+		{
+			name: "appends new results",
+			dst:  nil,
+			in:   []specResult{{Name: "a", File: "a_test.go", Line: 1}},
+			want: []specResult{{Name: "a", File: "a_test.go", Line: 1}},
+		},
+		{
+			name: "skips a result already seen",
+			dst:  []specResult{{Name: "a", File: "a_test.go", Line: 1}},
+			in:   []specResult{{Name: "a", File: "a_test.go", Line: 1}},
+			want: []specResult{{Name: "a", File: "a_test.go", Line: 1}},
+		},
+		{
+			name: "distinguishes same name at different locations",
+			dst:  nil,
+			in: []specResult{
+				{Name: "a", File: "a_test.go", Line: 1},
+				{Name: "a", File: "b_test.go", Line: 2},
+			},
+			want: []specResult{
+				{Name: "a", File: "a_test.go", Line: 1},
+				{Name: "a", File: "b_test.go", Line: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Seed seen by merging dst into an empty slice first, exactly
+			// as a caller would after a prior attempt's mergeSpecResults
+			// call, rather than assuming its key format here.
+			seen := make(map[string]bool)
+			dst := mergeSpecResults(nil, tt.dst, seen)
+			got := mergeSpecResults(dst, tt.in, seen)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeSpecResults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGinkgoJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	suites := []ginkgoSuiteReport{
+		{
+			SuiteDescription: "example suite",
+			SpecReports: []ginkgoSpecReport{
+				{LeafNodeText: "passes", State: specPassed},
+				{LeafNodeText: "fails", State: specFailed, LeafNodeLocation: ginkgoLocation{FileName: "foo_test.go", LineNumber: 10}, Failure: ginkgoFailure{Message: "boom"}},
+				{LeafNodeText: "flakes", State: specFlaky, LeafNodeLocation: ginkgoLocation{FileName: "bar_test.go", LineNumber: 20}},
+				{LeafNodeText: "pending", State: specPending},
+				{LeafNodeText: "skipped", State: specSkipped},
+			},
+		},
+	}
+	data, err := json.Marshal(suites)
+	if err != nil {
+		t.Fatalf("marshaling fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ginkgo.json"), data, 0644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	report, err := loadGinkgoJSONReport(dir, "example-repo")
+	if err != nil {
+		t.Fatalf("loadGinkgoJSONReport() error = %v", err)
+	}
+	if report.Passed != 1 || report.Pending != 1 || report.Skipped != 1 {
+		t.Errorf("counts = %+v, want Passed=1 Pending=1 Skipped=1", report)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Name != "fails" || report.Failed[0].Message != "boom" {
+		t.Errorf("Failed = %+v, want one spec named \"fails\" with message \"boom\"", report.Failed)
+	}
+	if len(report.Flaky) != 1 || report.Flaky[0].Name != "flakes" {
+		t.Errorf("Flaky = %+v, want one spec named \"flakes\"", report.Flaky)
+	}
+}
+
+func TestLoadGinkgoJSONReportMissingFile(t *testing.T) {
+	if _, err := loadGinkgoJSONReport(t.TempDir(), "example-repo"); err == nil {
+		t.Error("loadGinkgoJSONReport() error = nil, want an error for a missing report file")
+	}
+}
+
+func TestParseGinkgoV1Output(t *testing.T) {
+	output := "Running Suite\n" +
+		"• [FAIL] widget controller creates a widget\n" +
+		"some unrelated line\n" +
+		"• [FLAKY] widget controller deletes a widget\n"
+
+	report := parseGinkgoV1Output(output, "example-repo")
+	if len(report.Failed) != 1 || len(report.Flaky) != 1 {
+		t.Fatalf("parseGinkgoV1Output() = %+v, want 1 failed and 1 flaky", report)
+	}
+}
@@ -28,13 +28,25 @@ const (
 )
 
 var (
-	failLineRegex = regexp.MustCompile(`\[FAIL\]`)
-	flakyRegex    = regexp.MustCompile(`\[FLAKY\]`)
-	limit         = flag.Int("limit", 5, "Maximum number of concurrent test executions")
+	limit = flag.Int("limit", 5, "Maximum number of concurrent test executions")
 )
 
 func main() {
 	selectedRepo := flag.String("repo", "", "Specify a repository name to run tests on (e.g., 'cloud-ingress-operator')")
+	publishChecks := flag.Bool("publish-checks", false, "Publish a GitHub Check Run with test results for each repo")
+	checkName := flag.String("check-name", "ginkgo-e2e", "Name of the Check Run to publish when -publish-checks is set")
+	appID := flag.Int64("app-id", 0, "GitHub App ID for check-run publishing (NOT YET IMPLEMENTED - currently falls back to GITHUB_TOKEN regardless)")
+	cacheDir := flag.String("cache-dir", "", "Persist bare-repo mirrors here and reuse them across runs instead of cloning fresh each time")
+	outDir := flag.String("out-dir", ".", "Directory to write per-repo logs, summaries, and the aggregated index into")
+	httpAddr := flag.String("http", "", "Serve Prometheus /metrics and JSON /status on this address (e.g. ':8080'); disabled if empty")
+	sourceFlag := flag.String("source", "", "Comma-separated repo sources to query: github-org, github-search, gitlab, file (default: stdin, -repo, or an openshift org listing, as before)")
+	orgFlag := flag.String("org", "openshift", "GitHub org to list repos from for the github-org source")
+	includeFlag := flag.String("include", "operator", "Regex a repo name must match for the github-org source")
+	excludeFlag := flag.String("exclude", "", "Regex a repo name must not match for the github-org source")
+	searchQueryFlag := flag.String("search-query", "topic:operator", "GitHub search query for the github-search source")
+	fileFlag := flag.String("file", "-", "Path to a repo list for the file source (\"-\" for stdin)")
+	gitlabURLFlag := flag.String("gitlab-url", "https://gitlab.com", "Base URL of the GitLab instance for the gitlab source")
+	gitlabGroupFlag := flag.String("gitlab-group", "", "GitLab group (path or ID) to list projects from for the gitlab source")
 	flag.Parse()
 
 	ghToken := os.Getenv("GITHUB_TOKEN")
@@ -42,22 +54,80 @@ func main() {
 		log.Fatal("Error: GITHUB_TOKEN is not set. Exiting.")
 	}
 
+	var publisher *checkPublisher
+	if *publishChecks {
+		publisher = newCheckPublisher(newGitHubClient(ghToken, *appID), *checkName)
+	}
+
+	var cache *repoCache
+	if *cacheDir != "" {
+		var err error
+		cache, err = newRepoCache(*cacheDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize repo cache: %v", err)
+		}
+	}
+
+	tracker := newStatusTracker()
+	if *httpAddr != "" {
+		go func() {
+			if err := serveMetrics(*httpAddr, tracker); err != nil {
+				log.Printf("Metrics server on %s stopped: %v", *httpAddr, err)
+			}
+		}()
+		fmt.Println("Serving Prometheus metrics and status on", *httpAddr)
+	}
+
 	var repositories []string
 	var err error
 
-	stdinInfo, _ := os.Stdin.Stat()
-	if (stdinInfo.Mode() & os.ModeCharDevice) == 0 {
-		repositories, err = readReposFromStdin()
+	if *sourceFlag != "" {
+		var include, exclude *regexp.Regexp
+		if *includeFlag != "" {
+			include, err = regexp.Compile(*includeFlag)
+			if err != nil {
+				log.Fatalf("Invalid -include regex: %v", err)
+			}
+		}
+		if *excludeFlag != "" {
+			exclude, err = regexp.Compile(*excludeFlag)
+			if err != nil {
+				log.Fatalf("Invalid -exclude regex: %v", err)
+			}
+		}
+		sources, err := buildRepoSources(strings.Split(*sourceFlag, ","), sourceConfig{
+			ghClient:    newGitHubClient(ghToken, 0),
+			org:         *orgFlag,
+			include:     include,
+			exclude:     exclude,
+			searchQuery: *searchQueryFlag,
+			filePath:    *fileFlag,
+			gitlabURL:   *gitlabURLFlag,
+			gitlabToken: os.Getenv("GITLAB_TOKEN"),
+			gitlabGroup: *gitlabGroupFlag,
+		})
 		if err != nil {
-			log.Fatalf("Failed to read repos from stdin: %v", err)
+			log.Fatalf("Failed to configure repo sources: %v", err)
 		}
-	} else if *selectedRepo != "" {
-		repoURL := fmt.Sprintf("https://github.com/openshift/%s.git", *selectedRepo)
-		repositories = []string{repoURL}
-	} else {
-		repositories, err = fetchOperatorRepos()
+		repositories, err = discoverRepos(context.Background(), sources)
 		if err != nil {
-			log.Fatalf("Failed to fetch operator repos: %v", err)
+			log.Fatalf("Failed to discover repos: %v", err)
+		}
+	} else {
+		stdinInfo, _ := os.Stdin.Stat()
+		if (stdinInfo.Mode() & os.ModeCharDevice) == 0 {
+			repositories, err = readReposFromStdin()
+			if err != nil {
+				log.Fatalf("Failed to read repos from stdin: %v", err)
+			}
+		} else if *selectedRepo != "" {
+			repoURL := fmt.Sprintf("https://github.com/openshift/%s.git", *selectedRepo)
+			repositories = []string{repoURL}
+		} else {
+			repositories, err = fetchOperatorRepos()
+			if err != nil {
+				log.Fatalf("Failed to fetch operator repos: %v", err)
+			}
 		}
 	}
 
@@ -69,19 +139,10 @@ func main() {
 	sort.Strings(repositories)
 	fmt.Println("Found", len(repositories), "operator repos:")
 
-	reportFile, err := os.Create("test_report.txt")
-	if err != nil {
-		log.Fatalf("Failed to create report file: %v", err)
-	}
-	defer reportFile.Close()
-	writer := bufio.NewWriter(reportFile)
-
-	skippedFile, err := os.Create("skipped_repos.txt")
+	sink, err := newReportSink(*outDir)
 	if err != nil {
-		log.Fatalf("Failed to create skipped repos file: %v", err)
+		log.Fatalf("Failed to initialize report sink: %v", err)
 	}
-	defer skippedFile.Close()
-	skippedWriter := bufio.NewWriter(skippedFile)
 
 	reposFolder, _ := os.MkdirTemp("", "repos")
 	fmt.Println("Cloning repos to:", reposFolder)
@@ -96,16 +157,16 @@ func main() {
 		go func() {
 			defer wg.Done()
 			defer func() { <-sem }()
-			processRepo(repoURL, reposFolder, writer, skippedWriter)
+			processRepo(repoURL, reposFolder, sink, publisher, cache, tracker)
 		}()
 	}
 
 	wg.Wait()
-	writer.Flush()
-	skippedWriter.Flush()
+	if err := sink.flush(); err != nil {
+		log.Printf("Failed to flush report index: %v", err)
+	}
 
-	fmt.Println("\nTest execution completed. Results saved in test_report.txt")
-	fmt.Println("Skipped repos saved in skipped_repos.txt")
+	fmt.Printf("\nTest execution completed. Per-repo logs, summaries, and index saved in %s\n", *outDir)
 }
 
 func readReposFromStdin() ([]string, error) {
@@ -123,47 +184,91 @@ func readReposFromStdin() ([]string, error) {
 	return repos, nil
 }
 
-func processRepo(repoURL, reposFolder string, writer, skippedWriter *bufio.Writer) {
+// attemptResult is the outcome of a repo's attempt loop, handed back
+// from the goroutine that runs it over a channel so the caller never
+// reads state the goroutine might still be writing (see processRepo).
+type attemptResult struct {
+	report        *testReport
+	criticalError string
+	gotReport     bool
+	rawOutput     string
+}
+
+func processRepo(repoURL, reposFolder string, sink *reportSink, publisher *checkPublisher, cache *repoCache, tracker *statusTracker) {
 	repoName := getRepoName(repoURL)
 	repoPath := filepath.Join(reposFolder, repoName)
 
 	if repoName == skipRepoName {
 		fmt.Printf("Skipping repository: %s\n", repoName)
-		writer.WriteString(fmt.Sprintf("\n%s\nRepository skipped by policy.\n", repoName))
-		writer.Flush()
+		sink.record(repoOutcome{Repo: repoName, Status: "skipped_policy"})
 		return
 	}
 
-	fmt.Println("Cloning repository:", repoURL)
-	cmd := exec.Command("git", "clone", "--depth=1", repoURL, repoPath)
-	if err := cmd.Run(); err != nil {
-		fmt.Println("Repository not found or failed to clone:", repoURL)
-		writer.WriteString(fmt.Sprintf("\n%s\nRepository Not Found.\n", repoName))
-		writer.Flush()
-		return
+	tracker.start(repoName)
+	defer tracker.finish(repoName)
+
+	var sha string
+	if cache != nil {
+		var cachedPass bool
+		var cleanup func()
+		var err error
+		sha, cachedPass, cleanup, err = checkoutFromCache(cache, repoURL, repoName, repoPath)
+		if err != nil {
+			fmt.Println("Failed to prepare cached checkout for", repoName, ":", err)
+			clonesFailedTotal.Inc()
+			sink.record(repoOutcome{Repo: repoName, Status: "not_found", Message: err.Error()})
+			return
+		}
+		if cachedPass {
+			fmt.Println("Skipping", repoName, "- unchanged since last passing run at", sha)
+			sink.record(repoOutcome{Repo: repoName, Status: "cached_pass", Message: sha})
+			publishCheckResult(publisher, repoName, sha, conclusionSuccess, &testReport{Repo: repoName}, "")
+			return
+		}
+		defer cleanup()
+	} else {
+		fmt.Println("Cloning repository:", repoURL)
+		cmd := exec.Command("git", "clone", "--depth=1", repoURL, repoPath)
+		if err := cmd.Run(); err != nil {
+			fmt.Println("Repository not found or failed to clone:", repoURL)
+			clonesFailedTotal.Inc()
+			sink.record(repoOutcome{Repo: repoName, Status: "not_found"})
+			return
+		}
+		var shaErr error
+		sha, shaErr = gitHeadSHA(repoPath)
+		if shaErr != nil {
+			fmt.Println("Failed to resolve HEAD sha for", repoName, ":", shaErr)
+		}
 	}
+
 	testDir, err := getTestExecutionDir(repoPath)
 	if err != nil {
 		fmt.Println("Skipping repo (no valid e2e test directory found):", repoName)
-		skippedWriter.WriteString(fmt.Sprintf("%s\n", repoName))
-		skippedWriter.Flush()
+		sink.record(repoOutcome{Repo: repoName, Status: "skipped_no_e2e"})
+		publishCheckResult(publisher, repoName, sha, conclusionSkipped, &testReport{Repo: repoName}, "")
+		recordCacheResult(cache, repoName, sha, conclusionSkipped)
 		return
 	}
 
+	runStart := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
-	done := make(chan struct{})
-
-	var failedTests []string
-	var flakyTests []string
-	var criticalError string
+	resultCh := make(chan attemptResult, 1)
 
 	go func() {
-		uniqueFailures := make(map[string]bool)
-		uniqueFlaky := make(map[string]bool)
+		report := &testReport{Repo: repoName}
+		var criticalError string
+		var gotReport bool
+		var rawOutput strings.Builder
+		seenFailed := make(map[string]bool)
+		seenFlaky := make(map[string]bool)
 		for i := 0; i < 3; i++ {
+			tracker.setAttempt(repoName, i+1)
 			fmt.Printf("Running test for %s (Attempt %d/3) in directory %s\n", repoName, i+1, testDir)
-			output, err := runGinkgoTests(testDir)
+			output, err := runGinkgoTests(repoPath, testDir)
+			rawOutput.WriteString(fmt.Sprintf("--- attempt %d ---\n%s\n", i+1, output))
+			recordGinkgoExitCode(err)
 			if err != nil {
 				if exitErr, ok := err.(*exec.ExitError); ok {
 					switch exitErr.ExitCode() {
@@ -177,33 +282,132 @@ func processRepo(repoURL, reposFolder string, writer, skippedWriter *bufio.Write
 					break
 				}
 			}
-			failed, flaky := parseTestResults(output)
-			for _, line := range failed {
-				if !uniqueFailures[line] {
-					failedTests = append(failedTests, line)
-					uniqueFailures[line] = true
-				}
+			attempt, loadErr := loadGinkgoJSONReport(testDir, repoName)
+			if loadErr != nil && defaultGinkgoInvoker.versionFor(repoPath) == ginkgoVersionV1 {
+				attempt, loadErr = parseGinkgoV1Output(output, repoName), nil
 			}
-			for _, line := range flaky {
-				if !uniqueFlaky[line] {
-					flakyTests = append(flakyTests, line)
-					uniqueFlaky[line] = true
-				}
+			if loadErr != nil {
+				// No structured report was produced for this attempt
+				// (e.g. Ginkgo never got far enough to write one).
+				continue
 			}
+			gotReport = true
+			report.Failed = mergeSpecResults(report.Failed, attempt.Failed, seenFailed)
+			report.Flaky = mergeSpecResults(report.Flaky, attempt.Flaky, seenFlaky)
+			report.Passed, report.Pending, report.Skipped = attempt.Passed, attempt.Pending, attempt.Skipped
 		}
-		done <- struct{}{}
+		resultCh <- attemptResult{report: report, criticalError: criticalError, gotReport: gotReport, rawOutput: rawOutput.String()}
 	}()
 
+	// The attempt goroutine above keeps running past a timeout (it has no
+	// cancellation check), so the timeout branch must not touch any of
+	// its locals — it builds its own result instead of reading the
+	// goroutine's in-progress state, which would otherwise race it.
+	var timedOut bool
+	var result attemptResult
 	select {
 	case <-ctx.Done():
-		msg := "Test suite took too long (>3 minutes), skipping remaining attempts."
-		writer.WriteString(fmt.Sprintf("\n%s\n%s\n", repoName, msg))
-		writer.Flush()
-	case <-done:
-		testSummary := generateSummary(failedTests, flakyTests, criticalError)
-		writer.WriteString(fmt.Sprintf("\n%s\n%s\n", repoName, testSummary))
-		writer.Flush()
+		timedOut = true
+		result = attemptResult{report: &testReport{Repo: repoName}, criticalError: "Test suite took too long (>3 minutes), skipping remaining attempts."}
+		timeoutsTotal.Inc()
+	case result = <-resultCh:
+	}
+	testDurationSeconds.WithLabelValues(repoName).Observe(time.Since(runStart).Seconds())
+
+	report, criticalError, gotReport := result.report, result.criticalError, result.gotReport
+	logContent := result.rawOutput + "\n--- summary ---\n" + report.renderSummary(criticalError)
+	if err := sink.writeLog(repoName, logContent); err != nil {
+		fmt.Println("Failed to persist log for", repoName, ":", err)
 	}
+	if gotReport {
+		if err := report.writeJSON(sink.dir); err != nil {
+			fmt.Println("Failed to persist structured report for", repoName, ":", err)
+		}
+		if err := copyJUnitReport(testDir, sink.dir, repoName); err != nil {
+			fmt.Println("Failed to persist junit report for", repoName, ":", err)
+		}
+	}
+	failingSpecs.WithLabelValues(repoName).Set(float64(len(report.Failed)))
+	flakySpecs.WithLabelValues(repoName).Set(float64(len(report.Flaky)))
+
+	conclusion := conclusionFor(report, criticalError, timedOut)
+	status := "pass"
+	if timedOut {
+		status = "timeout"
+	} else if conclusion == conclusionFailure {
+		status = "fail"
+	}
+	sink.record(repoOutcome{Repo: repoName, Status: status, Failed: len(report.Failed), Flaky: len(report.Flaky), Message: criticalError})
+	publishCheckResult(publisher, repoName, sha, conclusion, report, criticalError)
+	recordCacheResult(cache, repoName, sha, conclusion)
+}
+
+// recordGinkgoExitCode tallies a Ginkgo invocation's exit code for the
+// ginkgo_runner_ginkgo_exit_codes_total metric.
+func recordGinkgoExitCode(err error) {
+	code := "0"
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = fmt.Sprintf("%d", exitErr.ExitCode())
+		} else {
+			code = "unknown"
+		}
+	}
+	ginkgoExitCodesTotal.WithLabelValues(code).Inc()
+}
+
+// checkoutFromCache fetches repoName's bare mirror (skipping the fetch
+// if the remote tip is unchanged) and checks sha out into repoPath as a
+// worktree. If the mirror's tip already matches the last run that
+// passed, it returns cachedPass=true and the caller can skip testing
+// entirely.
+func checkoutFromCache(cache *repoCache, repoURL, repoName, repoPath string) (sha string, cachedPass bool, cleanup func(), err error) {
+	sha, _, err = cache.ensureMirror(repoURL, repoName)
+	if err != nil {
+		return "", false, nil, err
+	}
+	if cache.cachedPass(repoName, sha) {
+		return sha, true, nil, nil
+	}
+	cleanup, err = cache.addWorktree(repoName, sha, repoPath)
+	if err != nil {
+		return "", false, nil, err
+	}
+	return sha, false, cleanup, nil
+}
+
+// recordCacheResult persists repoName's outcome in cache so the next
+// run can short-circuit an unchanged, already-passing repo.
+func recordCacheResult(cache *repoCache, repoName, sha string, conclusion checkConclusion) {
+	if cache == nil || sha == "" {
+		return
+	}
+	if err := cache.recordResult(repoName, sha, conclusion == conclusionSuccess); err != nil {
+		fmt.Println("Failed to record cache result for", repoName, ":", err)
+	}
+}
+
+// publishCheckResult publishes a Check Run for repoName if publisher is
+// configured and a commit sha was resolved; failures are logged, not
+// fatal, since check publishing is a best-effort side channel.
+func publishCheckResult(publisher *checkPublisher, repoName, sha string, conclusion checkConclusion, report *testReport, criticalError string) {
+	if publisher == nil || sha == "" {
+		return
+	}
+	if err := publisher.publish(context.Background(), "openshift", repoName, sha, conclusion, report, criticalError); err != nil {
+		fmt.Println("Failed to publish check run for", repoName, ":", err)
+	}
+}
+
+// gitHeadSHA returns the commit sha checked out at repoPath.
+func gitHeadSHA(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD sha: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
 }
 
 func fetchOperatorRepos() ([]string, error) {
@@ -264,51 +468,8 @@ func getTestExecutionDir(repoPath string) (string, error) {
 	return e2eFolder, nil
 }
 
-func runGinkgoTests(testDir string) (string, error) {
-	cmd := exec.Command("ginkgo", "-p", "-nodes=4", "--flake-attempts=3", "--tags=e2e,osde2e", "--no-color", "-v", "--trace", ".")
-	cmd.Dir = testDir
-	outputBytes, err := cmd.CombinedOutput()
-	return string(outputBytes), err
-}
-
-func parseTestResults(output string) ([]string, []string) {
-	var failed, flaky []string
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		switch {
-		case failLineRegex.MatchString(line):
-			failed = append(failed, line)
-		case flakyRegex.MatchString(line):
-			flaky = append(flaky, line)
-		}
-	}
-	return failed, flaky
-}
-
-func generateSummary(failed, flaky []string, criticalError string) string {
-	var summary strings.Builder
-	if criticalError != "" {
-		summary.WriteString(fmt.Sprintf("Critical Error:\n  - %s\n", criticalError))
-		return summary.String()
-	}
-	if len(failed) > 0 {
-		summary.WriteString("Failing Tests:\n")
-		for _, line := range failed {
-			summary.WriteString(fmt.Sprintf("  - %s\n", line))
-		}
-	}
-	if len(flaky) > 0 {
-		summary.WriteString("\nFlaky Tests:\n")
-		for _, line := range flaky {
-			summary.WriteString(fmt.Sprintf("  - %s\n", line))
-		}
-	}
-	if summary.Len() == 0 {
-		return "No failing or flaky tests detected."
-	}
-	return summary.String()
+func runGinkgoTests(repoPath, testDir string) (string, error) {
+	args := []string{"-p", "-nodes=4", "--flake-attempts=3", "--tags=e2e,osde2e", "--no-color", "-v", "--trace",
+		"--json-report=ginkgo.json", "--junit-report=junit.xml", "."}
+	return defaultGinkgoInvoker.run(repoPath, testDir, args)
 }
@@ -0,0 +1,130 @@
+// metrics.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	reposProcessedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ginkgo_runner_repos_processed_total",
+		Help: "Total number of repos the runner has finished processing.",
+	})
+	reposRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ginkgo_runner_repos_running",
+		Help: "Number of repos currently being cloned or tested.",
+	})
+	clonesFailedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ginkgo_runner_clones_failed_total",
+		Help: "Total number of repos that failed to clone.",
+	})
+	timeoutsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ginkgo_runner_timeouts_total",
+		Help: "Total number of repos whose test suite exceeded the timeout.",
+	})
+	testDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ginkgo_runner_test_duration_seconds",
+		Help:    "Wall-clock duration of a repo's full Ginkgo run (all attempts).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"repo"})
+	failingSpecs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ginkgo_runner_failing_specs",
+		Help: "Number of failing specs in the most recent run of a repo.",
+	}, []string{"repo"})
+	flakySpecs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ginkgo_runner_flaky_specs",
+		Help: "Number of flaky specs in the most recent run of a repo.",
+	}, []string{"repo"})
+	ginkgoExitCodesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ginkgo_runner_ginkgo_exit_codes_total",
+		Help: "Count of Ginkgo invocations by exit code.",
+	}, []string{"code"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		reposProcessedTotal,
+		reposRunning,
+		clonesFailedTotal,
+		timeoutsTotal,
+		testDurationSeconds,
+		failingSpecs,
+		flakySpecs,
+		ginkgoExitCodesTotal,
+	)
+}
+
+// inFlightRun is the /status snapshot of one repo currently being
+// processed.
+type inFlightRun struct {
+	Repo      string    `json:"repo"`
+	Attempt   int       `json:"attempt"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// statusTracker records which repos are currently in flight, for the
+// optional /status endpoint. Safe for concurrent use.
+type statusTracker struct {
+	mu      sync.Mutex
+	running map[string]*inFlightRun
+}
+
+func newStatusTracker() *statusTracker {
+	return &statusTracker{running: make(map[string]*inFlightRun)}
+}
+
+func (t *statusTracker) start(repoName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running[repoName] = &inFlightRun{Repo: repoName, Attempt: 0, StartedAt: time.Now()}
+	reposRunning.Inc()
+}
+
+func (t *statusTracker) setAttempt(repoName string, attempt int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if run, ok := t.running[repoName]; ok {
+		run.Attempt = attempt
+	}
+}
+
+func (t *statusTracker) finish(repoName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.running[repoName]; ok {
+		delete(t.running, repoName)
+		reposRunning.Dec()
+	}
+	reposProcessedTotal.Inc()
+}
+
+func (t *statusTracker) snapshot() []*inFlightRun {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	runs := make([]*inFlightRun, 0, len(t.running))
+	for _, run := range t.running {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func (t *statusTracker) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.snapshot())
+}
+
+// serveMetrics starts an HTTP server on addr exposing /metrics
+// (Prometheus) and /status (JSON snapshot of in-flight repos). It runs
+// until the process exits; callers should launch it in a goroutine.
+func serveMetrics(addr string, tracker *statusTracker) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/status", tracker.serveHTTP)
+	return http.ListenAndServe(addr, mux)
+}
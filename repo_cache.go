@@ -0,0 +1,146 @@
+// repo_cache.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// cacheEntry is the last-known-good state for one repo's bare mirror.
+type cacheEntry struct {
+	SHA    string `json:"sha"`
+	Passed bool   `json:"passed"`
+}
+
+// repoCache keeps a persistent bare-repo mirror per repo under dir,
+// along with an on-disk index of each repo's last-fetched sha and
+// whether its tests last passed, so unchanged repos can be short-
+// circuited without re-cloning or re-running Ginkgo.
+type repoCache struct {
+	dir string
+
+	mu    sync.Mutex
+	index map[string]cacheEntry
+}
+
+func newRepoCache(dir string) (*repoCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache dir: %w", err)
+	}
+	c := &repoCache{dir: dir, index: make(map[string]cacheEntry)}
+	if data, err := os.ReadFile(c.indexPath()); err == nil {
+		_ = json.Unmarshal(data, &c.index)
+	}
+	return c, nil
+}
+
+func (c *repoCache) indexPath() string {
+	return filepath.Join(c.dir, "index.json")
+}
+
+func (c *repoCache) mirrorPath(repoName string) string {
+	return filepath.Join(c.dir, repoName+".git")
+}
+
+// save atomically persists the index so a crash mid-write can't corrupt
+// it for the next run.
+func (c *repoCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(c.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cache index: %w", err)
+	}
+	tmp := c.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing cache index: %w", err)
+	}
+	return os.Rename(tmp, c.indexPath())
+}
+
+// recordResult updates repoName's cached sha and pass/fail state and
+// persists the index.
+func (c *repoCache) recordResult(repoName, sha string, passed bool) error {
+	c.mu.Lock()
+	c.index[repoName] = cacheEntry{SHA: sha, Passed: passed}
+	c.mu.Unlock()
+	return c.save()
+}
+
+// cachedPass reports whether repoName is known to have passed at sha
+// the last time it was tested.
+func (c *repoCache) cachedPass(repoName, sha string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.index[repoName]
+	return ok && entry.SHA == sha && entry.Passed
+}
+
+// remoteHeadSHA resolves repoURL's HEAD sha without cloning it.
+func remoteHeadSHA(repoURL string) (string, error) {
+	cmd := exec.Command("git", "ls-remote", repoURL, "HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("ls-remote %s: %w", repoURL, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("ls-remote %s: empty output", repoURL)
+	}
+	return fields[0], nil
+}
+
+// ensureMirror makes sure repoName's bare mirror exists under the cache
+// dir and is fetched up to date, skipping the fetch entirely when sha
+// already matches the cached tip. It returns the resolved remote sha.
+func (c *repoCache) ensureMirror(repoURL, repoName string) (sha string, fetched bool, err error) {
+	sha, err = remoteHeadSHA(repoURL)
+	if err != nil {
+		return "", false, err
+	}
+
+	c.mu.Lock()
+	entry, known := c.index[repoName]
+	c.mu.Unlock()
+	mirrorPath := c.mirrorPath(repoName)
+
+	if _, statErr := os.Stat(mirrorPath); os.IsNotExist(statErr) {
+		if err := exec.Command("git", "clone", "--mirror", repoURL, mirrorPath).Run(); err != nil {
+			return "", false, fmt.Errorf("mirroring %s: %w", repoURL, err)
+		}
+		return sha, true, nil
+	}
+
+	if known && entry.SHA == sha {
+		return sha, false, nil
+	}
+
+	cmd := exec.Command("git", "--git-dir="+mirrorPath, "fetch", "--prune", "origin")
+	if err := cmd.Run(); err != nil {
+		return "", false, fmt.Errorf("fetching %s: %w", repoURL, err)
+	}
+	return sha, true, nil
+}
+
+// addWorktree checks sha out of repoName's mirror into worktreeDir. The
+// returned cleanup func removes the worktree again; callers should
+// defer it once the test run is done with the checkout.
+func (c *repoCache) addWorktree(repoName, sha, worktreeDir string) (func(), error) {
+	mirrorPath := c.mirrorPath(repoName)
+	cmd := exec.Command("git", "--git-dir="+mirrorPath, "worktree", "add", "--detach", "--force", worktreeDir, sha)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("adding worktree for %s: %w", repoName, err)
+	}
+	cleanup := func() {
+		removeCmd := exec.Command("git", "--git-dir="+mirrorPath, "worktree", "remove", "--force", worktreeDir)
+		if err := removeCmd.Run(); err != nil {
+			os.RemoveAll(worktreeDir)
+		}
+	}
+	return cleanup, nil
+}
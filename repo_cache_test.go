@@ -0,0 +1,71 @@
+// repo_cache_test.go
+package main
+
+import "testing"
+
+func TestCachedPass(t *testing.T) {
+	tests := []struct {
+		name  string
+		index map[string]cacheEntry
+		repo  string
+		sha   string
+		want  bool
+	}{
+		{
+			name:  "passed at matching sha",
+			index: map[string]cacheEntry{"repo-a": {SHA: "abc123", Passed: true}},
+			repo:  "repo-a",
+			sha:   "abc123",
+			want:  true,
+		},
+		{
+			name:  "failed at matching sha",
+			index: map[string]cacheEntry{"repo-a": {SHA: "abc123", Passed: false}},
+			repo:  "repo-a",
+			sha:   "abc123",
+			want:  false,
+		},
+		{
+			name:  "passed but sha has moved on",
+			index: map[string]cacheEntry{"repo-a": {SHA: "abc123", Passed: true}},
+			repo:  "repo-a",
+			sha:   "def456",
+			want:  false,
+		},
+		{
+			name:  "repo not in index",
+			index: map[string]cacheEntry{},
+			repo:  "repo-a",
+			sha:   "abc123",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &repoCache{dir: t.TempDir(), index: tt.index}
+			if got := c.cachedPass(tt.repo, tt.sha); got != tt.want {
+				t.Errorf("cachedPass(%q, %q) = %v, want %v", tt.repo, tt.sha, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoCacheSaveAndReload(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newRepoCache(dir)
+	if err != nil {
+		t.Fatalf("newRepoCache() error = %v", err)
+	}
+	if err := c.recordResult("repo-a", "abc123", true); err != nil {
+		t.Fatalf("recordResult() error = %v", err)
+	}
+
+	reloaded, err := newRepoCache(dir)
+	if err != nil {
+		t.Fatalf("newRepoCache() on reload error = %v", err)
+	}
+	if !reloaded.cachedPass("repo-a", "abc123") {
+		t.Error("cachedPass() on reloaded cache = false, want true after recordResult persisted it")
+	}
+}
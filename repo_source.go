@@ -0,0 +1,224 @@
+// repo_source.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// RepoSource discovers candidate operator repo clone URLs from some
+// backend: a GitHub org listing, a GitHub search query, a GitLab group,
+// or a static file/stdin list.
+type RepoSource interface {
+	Discover(ctx context.Context) ([]string, error)
+}
+
+// githubOrgSource lists every repo in a GitHub org and keeps the ones
+// matching include and not matching exclude.
+type githubOrgSource struct {
+	client  *github.Client
+	org     string
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+func (s *githubOrgSource) Discover(ctx context.Context) ([]string, error) {
+	opt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var repos []string
+	for {
+		page, resp, err := s.client.Repositories.ListByOrg(ctx, s.org, opt)
+		if err != nil {
+			return nil, fmt.Errorf("listing repos for org %s: %w", s.org, err)
+		}
+		for _, r := range page {
+			name := r.GetName()
+			if s.include != nil && !s.include.MatchString(name) {
+				continue
+			}
+			if s.exclude != nil && s.exclude.MatchString(name) {
+				continue
+			}
+			repos = append(repos, fmt.Sprintf("https://github.com/%s/%s.git", s.org, name))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// githubSearchSource finds repos via GitHub's repo search, e.g.
+// "org:openshift topic:operator".
+type githubSearchSource struct {
+	client *github.Client
+	query  string
+}
+
+func (s *githubSearchSource) Discover(ctx context.Context) ([]string, error) {
+	opt := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var repos []string
+	for {
+		result, resp, err := s.client.Search.Repositories(ctx, s.query, opt)
+		if err != nil {
+			return nil, fmt.Errorf("searching repos for %q: %w", s.query, err)
+		}
+		for _, r := range result.Repositories {
+			repos = append(repos, r.GetCloneURL())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// fileSource reads repo names or URLs from a file, one per line. Path
+// "-" reads from stdin, matching the bare repo-name format the runner
+// already accepted there.
+type fileSource struct {
+	path string
+}
+
+func (s *fileSource) Discover(ctx context.Context) ([]string, error) {
+	if s.path == "-" {
+		return readRepoLines(os.Stdin)
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("opening repo list %s: %w", s.path, err)
+	}
+	defer f.Close()
+	return readRepoLines(f)
+}
+
+func readRepoLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	var repos []string
+	for scanner.Scan() {
+		repo := strings.TrimSpace(scanner.Text())
+		if repo == "" {
+			continue
+		}
+		if strings.Contains(repo, "://") {
+			repos = append(repos, repo)
+			continue
+		}
+		repos = append(repos, fmt.Sprintf("https://github.com/openshift/%s.git", repo))
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// gitlabSource finds projects in a GitLab group via the GitLab REST
+// API, mirroring githubOrgSource's org-listing pattern.
+type gitlabSource struct {
+	baseURL string
+	token   string
+	group   string
+}
+
+func (s *gitlabSource) Discover(ctx context.Context) ([]string, error) {
+	// Subgroup paths (e.g. "team/subteam") must be percent-encoded as a
+	// single path segment, with the "/" escaped to %2F, or GitLab resolves
+	// them as nested URL path segments instead of a group path.
+	endpoint := fmt.Sprintf("%s/api/v4/groups/%s/projects?per_page=100", strings.TrimRight(s.baseURL, "/"), url.PathEscape(s.group))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building gitlab request: %w", err)
+	}
+	if s.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", s.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("listing gitlab group %s: %w", s.group, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing gitlab group %s: unexpected status %s", s.group, resp.Status)
+	}
+	var projects []struct {
+		HTTPURLToRepo string `json:"http_url_to_repo"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+		return nil, fmt.Errorf("decoding gitlab response: %w", err)
+	}
+	repos := make([]string, 0, len(projects))
+	for _, p := range projects {
+		repos = append(repos, p.HTTPURLToRepo)
+	}
+	return repos, nil
+}
+
+// sourceConfig carries every flag a RepoSource might need; buildRepoSources
+// only reads the fields relevant to the source names it's building.
+type sourceConfig struct {
+	ghClient    *github.Client
+	org         string
+	include     *regexp.Regexp
+	exclude     *regexp.Regexp
+	searchQuery string
+	filePath    string
+	gitlabURL   string
+	gitlabToken string
+	gitlabGroup string
+}
+
+// buildRepoSources turns the source names of a -source flag value,
+// already split on commas, into the RepoSource implementations to
+// query, in order.
+func buildRepoSources(names []string, cfg sourceConfig) ([]RepoSource, error) {
+	var sources []RepoSource
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "github-org":
+			sources = append(sources, &githubOrgSource{client: cfg.ghClient, org: cfg.org, include: cfg.include, exclude: cfg.exclude})
+		case "github-search":
+			sources = append(sources, &githubSearchSource{client: cfg.ghClient, query: cfg.searchQuery})
+		case "gitlab":
+			if cfg.gitlabGroup == "" {
+				return nil, fmt.Errorf("-source=gitlab requires -gitlab-group")
+			}
+			sources = append(sources, &gitlabSource{baseURL: cfg.gitlabURL, token: cfg.gitlabToken, group: cfg.gitlabGroup})
+		case "file":
+			sources = append(sources, &fileSource{path: cfg.filePath})
+		default:
+			return nil, fmt.Errorf("unknown repo source %q", name)
+		}
+	}
+	return sources, nil
+}
+
+// discoverRepos runs every source in order and returns the deduplicated
+// union of their results.
+func discoverRepos(ctx context.Context, sources []RepoSource) ([]string, error) {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, source := range sources {
+		found, err := source.Discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range found {
+			if !seen[repo] {
+				repos = append(repos, repo)
+				seen[repo] = true
+			}
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,100 @@
+// repo_source_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+// fakeSource is a RepoSource test double that returns a canned list or
+// error, so discoverRepos's dedup logic can be tested without a network
+// call.
+type fakeSource struct {
+	repos []string
+	err   error
+}
+
+func (s *fakeSource) Discover(ctx context.Context) ([]string, error) {
+	return s.repos, s.err
+}
+
+func TestDiscoverReposDedups(t *testing.T) {
+	sources := []RepoSource{
+		&fakeSource{repos: []string{"https://github.com/openshift/a.git", "https://github.com/openshift/b.git"}},
+		&fakeSource{repos: []string{"https://github.com/openshift/b.git", "https://github.com/openshift/c.git"}},
+	}
+
+	got, err := discoverRepos(context.Background(), sources)
+	if err != nil {
+		t.Fatalf("discoverRepos() error = %v", err)
+	}
+	want := []string{
+		"https://github.com/openshift/a.git",
+		"https://github.com/openshift/b.git",
+		"https://github.com/openshift/c.git",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverReposPropagatesError(t *testing.T) {
+	sources := []RepoSource{&fakeSource{err: errBoom}}
+	if _, err := discoverRepos(context.Background(), sources); err != errBoom {
+		t.Errorf("discoverRepos() error = %v, want %v", err, errBoom)
+	}
+}
+
+func TestBuildRepoSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		cfg     sourceConfig
+		wantErr bool
+	}{
+		{
+			name:  "file source",
+			names: []string{"file"},
+			cfg:   sourceConfig{filePath: "-"},
+		},
+		{
+			name:  "gitlab source with group",
+			names: []string{"gitlab"},
+			cfg:   sourceConfig{gitlabGroup: "team/subteam"},
+		},
+		{
+			name:    "gitlab source without group is an error",
+			names:   []string{"gitlab"},
+			cfg:     sourceConfig{},
+			wantErr: true,
+		},
+		{
+			name:    "unknown source is an error",
+			names:   []string{"carrier-pigeon"},
+			cfg:     sourceConfig{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := buildRepoSources(tt.names, tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("buildRepoSources() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildRepoSources() error = %v", err)
+			}
+			if len(sources) != len(tt.names) {
+				t.Errorf("buildRepoSources() returned %d sources, want %d", len(sources), len(tt.names))
+			}
+		})
+	}
+}
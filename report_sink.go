@@ -0,0 +1,92 @@
+// report_sink.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// repoOutcome is one repo's entry in the aggregated index.
+type repoOutcome struct {
+	Repo    string `json:"repo"`
+	Status  string `json:"status"`
+	Failed  int    `json:"failed"`
+	Flaky   int    `json:"flaky"`
+	Message string `json:"message,omitempty"`
+}
+
+// reportSink replaces the single bufio.Writer test_report.txt (shared,
+// unsynchronized, across every goroutine) with one raw log file and one
+// structured summary per repo, plus an index aggregated atomically once
+// every repo is done. writeLog and record are safe to call from
+// concurrent goroutines; flush is not safe against a concurrent flush.
+type reportSink struct {
+	dir string
+
+	mu       sync.Mutex
+	outcomes []repoOutcome
+}
+
+func newReportSink(dir string) (*reportSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating out dir: %w", err)
+	}
+	return &reportSink{dir: dir}, nil
+}
+
+// writeLog persists repoName's raw Ginkgo output as <repo>.log.
+func (s *reportSink) writeLog(repoName, output string) error {
+	return os.WriteFile(filepath.Join(s.dir, repoName+".log"), []byte(output), 0644)
+}
+
+// record appends repoName's outcome for the final index.
+func (s *reportSink) record(outcome repoOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcomes = append(s.outcomes, outcome)
+}
+
+// flush atomically writes index.json and index.md once every repo has
+// finished, so a run killed partway through never leaves a half-written
+// index behind.
+func (s *reportSink) flush() error {
+	s.mu.Lock()
+	outcomes := append([]repoOutcome(nil), s.outcomes...)
+	s.mu.Unlock()
+
+	sort.Slice(outcomes, func(i, j int) bool { return outcomes[i].Repo < outcomes[j].Repo })
+
+	data, err := json.MarshalIndent(outcomes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling index: %w", err)
+	}
+	if err := writeAtomic(filepath.Join(s.dir, "index.json"), data); err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(s.dir, "index.md"), []byte(renderIndexMarkdown(outcomes)))
+}
+
+func renderIndexMarkdown(outcomes []repoOutcome) string {
+	var b strings.Builder
+	b.WriteString("| Repo | Status | Failed | Flaky |\n")
+	b.WriteString("|------|--------|-------:|------:|\n")
+	for _, o := range outcomes {
+		b.WriteString(fmt.Sprintf("| %s | %s | %d | %d |\n", o.Repo, o.Status, o.Failed, o.Flaky))
+	}
+	return b.String()
+}
+
+// writeAtomic writes data to a temp file next to path and renames it
+// into place, so readers never see a partially written file.
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
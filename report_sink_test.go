@@ -0,0 +1,57 @@
+// report_sink_test.go
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderIndexMarkdown(t *testing.T) {
+	outcomes := []repoOutcome{
+		{Repo: "repo-a", Status: "pass", Failed: 0, Flaky: 0},
+		{Repo: "repo-b", Status: "fail", Failed: 2, Flaky: 1},
+	}
+
+	md := renderIndexMarkdown(outcomes)
+	for _, want := range []string{"| repo-a | pass | 0 | 0 |", "| repo-b | fail | 2 | 1 |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("renderIndexMarkdown() missing row %q in:\n%s", want, md)
+		}
+	}
+}
+
+func TestReportSinkFlushWritesSortedIndex(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newReportSink(dir)
+	if err != nil {
+		t.Fatalf("newReportSink() error = %v", err)
+	}
+	sink.record(repoOutcome{Repo: "repo-b", Status: "pass"})
+	sink.record(repoOutcome{Repo: "repo-a", Status: "fail", Failed: 1})
+
+	if err := sink.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatalf("reading index.json: %v", err)
+	}
+	var outcomes []repoOutcome
+	if err := json.Unmarshal(data, &outcomes); err != nil {
+		t.Fatalf("unmarshaling index.json: %v", err)
+	}
+	if len(outcomes) != 2 || outcomes[0].Repo != "repo-a" || outcomes[1].Repo != "repo-b" {
+		t.Errorf("index.json outcomes = %+v, want repo-a then repo-b", outcomes)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "index.md")); err != nil {
+		t.Errorf("index.md was not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.json.tmp")); !os.IsNotExist(err) {
+		t.Error("index.json.tmp was left behind; writeAtomic should have renamed it away")
+	}
+}
@@ -0,0 +1,160 @@
+// reporter.go
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/oauth2"
+)
+
+// checkConclusion is the outcome reported for a repo's Check Run.
+type checkConclusion string
+
+const (
+	conclusionSuccess checkConclusion = "success"
+	conclusionFailure checkConclusion = "failure"
+	conclusionTimeout checkConclusion = "timed_out"
+	conclusionSkipped checkConclusion = "skipped"
+)
+
+// checkPublisher publishes per-repo test outcomes back to GitHub as
+// Check Runs on the commit the runner actually tested.
+type checkPublisher struct {
+	client    *github.Client
+	checkName string
+}
+
+func newCheckPublisher(client *github.Client, checkName string) *checkPublisher {
+	return &checkPublisher{client: client, checkName: checkName}
+}
+
+// newGitHubClient builds the client a checkPublisher authenticates with.
+//
+// NOTE: -app-id is accepted as a flag but GitHub App (JWT/installation
+// token) authentication is NOT implemented — it needs a dependency this
+// repo doesn't vendor yet. Passing -app-id has no effect beyond the
+// warning below; authentication always falls back to the PAT in
+// GITHUB_TOKEN. This is a known, intentional scope cut, not a bug.
+func newGitHubClient(ghToken string, appID int64) *github.Client {
+	if appID != 0 {
+		fmt.Printf("Warning: -app-id=%d set but GitHub App authentication is not implemented yet (falling back to GITHUB_TOKEN). See newGitHubClient.\n", appID)
+	}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: ghToken})
+	tc := oauth2.NewClient(context.Background(), ts)
+	return github.NewClient(tc)
+}
+
+// maxAnnotationsPerRequest is GitHub's limit on the number of
+// annotations accepted in a single Create/Update Check Run call.
+// Additional annotations have to be attached via follow-up Update Check
+// Run calls.
+const maxAnnotationsPerRequest = 50
+
+// publish creates a completed Check Run for owner/repoName@sha summarizing
+// report, with annotations pinned to each failing or flaky spec's
+// file:line. When there are more than maxAnnotationsPerRequest
+// annotations, the rest are attached via follow-up update calls so the
+// publish doesn't fail outright on a large e2e suite.
+func (p *checkPublisher) publish(ctx context.Context, owner, repoName, sha string, conclusion checkConclusion, report *testReport, criticalError string) error {
+	annotations := checkAnnotations(report)
+	first, rest := batchAnnotations(annotations, maxAnnotationsPerRequest)
+
+	opt := github.CreateCheckRunOptions{
+		Name:       p.checkName,
+		HeadSHA:    sha,
+		Status:     github.String("completed"),
+		Conclusion: github.String(string(conclusion)),
+		Output: &github.CheckRunOutput{
+			Title:       github.String(fmt.Sprintf("%s: %s", p.checkName, conclusion)),
+			Summary:     github.String(checkSummary(report, criticalError)),
+			Annotations: first,
+		},
+	}
+	checkRun, _, err := p.client.Checks.CreateCheckRun(ctx, owner, repoName, opt)
+	if err != nil {
+		return fmt.Errorf("publishing check run for %s: %w", repoName, err)
+	}
+
+	for _, batch := range rest {
+		updateOpt := github.UpdateCheckRunOptions{
+			Name: p.checkName,
+			Output: &github.CheckRunOutput{
+				Title:       github.String(fmt.Sprintf("%s: %s", p.checkName, conclusion)),
+				Summary:     github.String(checkSummary(report, criticalError)),
+				Annotations: batch,
+			},
+		}
+		if _, _, err := p.client.Checks.UpdateCheckRun(ctx, owner, repoName, checkRun.GetID(), updateOpt); err != nil {
+			return fmt.Errorf("publishing annotation batch for %s: %w", repoName, err)
+		}
+	}
+	return nil
+}
+
+// batchAnnotations splits annotations into the first maxAnnotationsPerRequest-sized
+// slice (for the initial create call) and the remaining slices
+// chunked to the same size (for follow-up update calls).
+func batchAnnotations(annotations []*github.CheckRunAnnotation, size int) (first []*github.CheckRunAnnotation, rest [][]*github.CheckRunAnnotation) {
+	if len(annotations) <= size {
+		return annotations, nil
+	}
+	first = annotations[:size]
+	remaining := annotations[size:]
+	for len(remaining) > 0 {
+		n := size
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		rest = append(rest, remaining[:n])
+		remaining = remaining[n:]
+	}
+	return first, rest
+}
+
+func checkSummary(report *testReport, criticalError string) string {
+	if criticalError != "" {
+		return fmt.Sprintf("Critical error: %s", criticalError)
+	}
+	return fmt.Sprintf("%d failing, %d flaky, %d passed", len(report.Failed), len(report.Flaky), report.Passed)
+}
+
+func checkAnnotations(report *testReport) []*github.CheckRunAnnotation {
+	var annotations []*github.CheckRunAnnotation
+	appendAll := func(results []specResult, level string) {
+		for _, r := range results {
+			if r.File == "" {
+				continue
+			}
+			line := r.Line
+			if line == 0 {
+				line = 1
+			}
+			annotations = append(annotations, &github.CheckRunAnnotation{
+				Path:            github.String(r.File),
+				StartLine:       github.Int(line),
+				EndLine:         github.Int(line),
+				AnnotationLevel: github.String(level),
+				Title:           github.String(r.Name),
+				Message:         github.String(r.Message),
+			})
+		}
+	}
+	appendAll(report.Failed, "failure")
+	appendAll(report.Flaky, "warning")
+	return annotations
+}
+
+// conclusionFor maps a repo's run outcome to the Check Run conclusion
+// GitHub expects.
+func conclusionFor(report *testReport, criticalError string, timedOut bool) checkConclusion {
+	switch {
+	case timedOut:
+		return conclusionTimeout
+	case criticalError != "", len(report.Failed) > 0:
+		return conclusionFailure
+	default:
+		return conclusionSuccess
+	}
+}